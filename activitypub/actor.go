@@ -0,0 +1,108 @@
+// Package activitypub implements just enough of the ActivityPub protocol to
+// let the bot's forum-post alerts be followed natively from Mastodon,
+// Pleroma and other fediverse servers.
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+)
+
+const publicKeyBits = 2048
+
+// Actor is the ActivityPub actor document served at /actor.
+type Actor struct {
+	Context           []string  `json:"@context"`
+	ID                string    `json:"id"`
+	Type              string    `json:"type"`
+	PreferredUsername string    `json:"preferredUsername"`
+	Inbox             string    `json:"inbox"`
+	Outbox            string    `json:"outbox"`
+	Followers         string    `json:"followers"`
+	PublicKey         PublicKey `json:"publicKey"`
+}
+
+// PublicKey is the publicKey block embedded in an Actor document.
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// keyPairDoc is how the actor's RSA keypair is persisted in Mongo so it
+// survives restarts and stays stable across the fleet.
+type keyPairDoc struct {
+	ID         string `bson:"_id"`
+	PrivateKey []byte `bson:"privateKey"`
+	PublicKey  []byte `bson:"publicKey"`
+}
+
+// loadOrCreateKeyPair returns the actor's RSA keypair, generating and
+// persisting one on first use.
+func loadOrCreateKeyPair(keys *mgo.Collection, actorID string) (*rsa.PrivateKey, []byte, error) {
+	var doc keyPairDoc
+	err := keys.FindId(actorID).One(&doc)
+	if err == nil {
+		privateKey, parseErr := x509.ParsePKCS1PrivateKey(doc.PrivateKey)
+		if parseErr != nil {
+			return nil, nil, errors.Wrap(parseErr, "failed to parse persisted actor private key")
+		}
+		return privateKey, doc.PublicKey, nil
+	}
+	if err != mgo.ErrNotFound {
+		return nil, nil, errors.Wrap(err, "failed to load actor keypair")
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, publicKeyBits)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate actor keypair")
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal actor public key")
+	}
+	publicKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+
+	doc = keyPairDoc{
+		ID:         actorID,
+		PrivateKey: x509.MarshalPKCS1PrivateKey(privateKey),
+		PublicKey:  publicKeyPem,
+	}
+	if _, err := keys.UpsertId(actorID, doc); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to persist actor keypair")
+	}
+
+	return privateKey, publicKeyPem, nil
+}
+
+// actorDocument builds the Actor document served at /actor.
+func (s *Server) actorDocument() Actor {
+	return Actor{
+		Context: []string{
+			"https://www.w3.org/ns/activitystreams",
+			"https://w3id.org/security/v1",
+		},
+		ID:                s.actorURL(),
+		Type:              "Service",
+		PreferredUsername: s.username,
+		Inbox:             s.actorURL() + "/inbox",
+		Outbox:            s.actorURL() + "/outbox",
+		Followers:         s.actorURL() + "/followers",
+		PublicKey: PublicKey{
+			ID:           s.actorURL() + "#main-key",
+			Owner:        s.actorURL(),
+			PublicKeyPem: string(s.publicKeyPem),
+		},
+	}
+}
+
+func (s *Server) actorURL() string {
+	return fmt.Sprintf("https://%s/actor", s.host)
+}
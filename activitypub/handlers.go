@@ -0,0 +1,343 @@
+package activitypub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const activityJSONType = `application/activity+json; charset=utf-8`
+
+func (s *Server) handleActor(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", activityJSONType)
+	json.NewEncoder(w).Encode(s.actorDocument())
+}
+
+// webfingerResource is the response to GET /.well-known/webfinger?resource=acct:user@host
+type webfingerResource struct {
+	Subject string          `json:"subject"`
+	Links   []webfingerLink `json:"links"`
+}
+
+type webfingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+func (s *Server) handleWebfinger(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	expected := fmt.Sprintf("acct:%s@%s", s.username, s.host)
+	if resource != expected {
+		http.Error(w, "resource not found", http.StatusNotFound)
+		return
+	}
+
+	resp := webfingerResource{
+		Subject: expected,
+		Links: []webfingerLink{
+			{Rel: "self", Type: activityJSONType, Href: s.actorURL()},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/jrd+json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// follower is a remote actor that follows our local actor, persisted so
+// delivery survives restarts.
+type follower struct {
+	ActorID string `bson:"actorId"`
+	Inbox   string `bson:"inbox"`
+}
+
+// activity is the minimal envelope we need to read Follow/Undo Follow
+// activities out of an inbox POST.
+type activity struct {
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+func (s *Server) handleInbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	var act activity
+	if err := json.Unmarshal(body, &act); err != nil {
+		http.Error(w, "malformed activity", http.StatusBadRequest)
+		return
+	}
+
+	// Follow/Undo change federation state (who receives future Publish
+	// deliveries), so they must be signed by the actor they claim to be
+	// from before we trust them.
+	switch act.Type {
+	case "Follow", "Undo":
+		if err := s.verifyInboxSignature(r, body, act.Actor); err != nil {
+			s.logger.Error("rejected inbox activity with invalid signature")
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	switch act.Type {
+	case "Follow":
+		if err := s.addFollower(act.Actor); err != nil {
+			s.logger.Error("failed to record follower")
+			http.Error(w, "failed to record follower", http.StatusInternalServerError)
+			return
+		}
+		go s.deliverAccept(act)
+	case "Undo":
+		// We only care about Undo{Follow}; other undo types are ignored.
+		var inner activity
+		if err := json.Unmarshal(act.Object, &inner); err == nil && inner.Type == "Follow" {
+			if err := s.removeFollower(act.Actor); err != nil {
+				s.logger.Error("failed to remove follower")
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) addFollower(actorID string) error {
+	inbox, err := s.resolveInbox(actorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.followers.UpsertId(actorID, follower{ActorID: actorID, Inbox: inbox})
+	return err
+}
+
+func (s *Server) removeFollower(actorID string) error {
+	return s.followers.RemoveId(actorID)
+}
+
+// resolveInbox fetches the remote actor document to find its inbox URL.
+func (s *Server) resolveInbox(actorID string) (string, error) {
+	remote, err := s.fetchActor(actorID)
+	if err != nil {
+		return "", err
+	}
+
+	return remote.Inbox, nil
+}
+
+// fetchActor does an SSRF-guarded GET of a remote actor document. actorID is
+// attacker-supplied (it comes straight off an inbox POST), so it's validated
+// against loopback/link-local/private addresses before we ever dial it.
+func (s *Server) fetchActor(actorID string) (Actor, error) {
+	if err := validateRemoteActorURL(actorID); err != nil {
+		return Actor{}, errors.Wrap(err, "refusing to fetch actor")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, actorID, nil)
+	if err != nil {
+		return Actor{}, err
+	}
+	req.Header.Set("Accept", activityJSONType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Actor{}, err
+	}
+	defer resp.Body.Close()
+
+	var remote Actor
+	if err := json.NewDecoder(resp.Body).Decode(&remote); err != nil {
+		return Actor{}, err
+	}
+
+	return remote, nil
+}
+
+// validateRemoteActorURL rejects actor URLs that don't point at a public
+// HTTPS host, so a forged Follow/Undo actor or keyId can't be used to make
+// this server fetch loopback, link-local or other private-network addresses.
+func validateRemoteActorURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.Wrap(err, "invalid actor URL")
+	}
+	if u.Scheme != "https" {
+		return errors.Errorf("actor URL must use https, got %q", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("actor URL has no host")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve actor host")
+	}
+	for _, ip := range ips {
+		if isDisallowedActorIP(ip) {
+			return errors.Errorf("actor host %s resolves to a disallowed address", host)
+		}
+	}
+
+	return nil
+}
+
+// isDisallowedActorIP reports whether ip is loopback, link-local, unspecified
+// or otherwise private, i.e. not a legitimate address for a public fediverse
+// server to be reachable on.
+func isDisallowedActorIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsPrivate()
+}
+
+func (s *Server) deliverAccept(follow activity) {
+	accept := map[string]interface{}{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"id":       s.actorURL() + "/accepts/" + strings.TrimPrefix(follow.Actor, "https://"),
+		"type":     "Accept",
+		"actor":    s.actorURL(),
+		"object":   follow,
+	}
+
+	inbox, err := s.resolveInbox(follow.Actor)
+	if err != nil {
+		s.logger.Error("failed to resolve follower inbox for accept")
+		return
+	}
+
+	if err := s.deliver(inbox, accept); err != nil {
+		s.logger.Error("failed to deliver accept activity")
+	}
+}
+
+// outboxPageSize is how many activities each OrderedCollectionPage carries.
+const outboxPageSize = 50
+
+// orderedCollection is a top-level ActivityStreams OrderedCollection. First
+// points readers at a separate OrderedCollectionPage for collections too
+// large to embed (the outbox); OrderedItems embeds them directly for
+// collections small enough not to need paging (the followers list).
+type orderedCollection struct {
+	Context      string        `json:"@context"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	TotalItems   int           `json:"totalItems"`
+	First        string        `json:"first,omitempty"`
+	OrderedItems []interface{} `json:"orderedItems,omitempty"`
+}
+
+// handleFollowers serves the local actor's followers as a single, unpaged
+// OrderedCollection of follower actor IDs. The list stays small enough in
+// practice (one Discord bot's fediverse followers) that paging isn't
+// warranted the way it is for the outbox.
+func (s *Server) handleFollowers(w http.ResponseWriter, r *http.Request) {
+	var followers []follower
+	if err := s.followers.Find(nil).All(&followers); err != nil {
+		http.Error(w, "failed to load followers", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]interface{}, len(followers))
+	for i, f := range followers {
+		items[i] = f.ActorID
+	}
+
+	w.Header().Set("Content-Type", activityJSONType)
+	json.NewEncoder(w).Encode(orderedCollection{
+		Context:      "https://www.w3.org/ns/activitystreams",
+		ID:           s.actorURL() + "/followers",
+		Type:         "OrderedCollection",
+		TotalItems:   len(items),
+		OrderedItems: items,
+	})
+}
+
+// orderedCollectionPage is one page of the outbox, newest-first. Next, when
+// present, is the URL of the page holding older activities.
+type orderedCollectionPage struct {
+	Context      string        `json:"@context"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	PartOf       string        `json:"partOf"`
+	Next         string        `json:"next,omitempty"`
+	OrderedItems []interface{} `json:"orderedItems"`
+}
+
+// handleOutbox serves the outbox as a paginated OrderedCollection: a bare
+// GET returns the collection summary plus a link to the first page, and
+// ?page=true (optionally with &before=<publishedAt>) returns up to
+// outboxPageSize activities older than the cursor.
+func (s *Server) handleOutbox(w http.ResponseWriter, r *http.Request) {
+	outboxURL := s.actorURL() + "/outbox"
+
+	if r.URL.Query().Get("page") == "" {
+		count, err := s.outbox.Count()
+		if err != nil {
+			http.Error(w, "failed to load outbox", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", activityJSONType)
+		json.NewEncoder(w).Encode(orderedCollection{
+			Context:    "https://www.w3.org/ns/activitystreams",
+			ID:         outboxURL,
+			Type:       "OrderedCollection",
+			TotalItems: count,
+			First:      outboxURL + "?page=true",
+		})
+		return
+	}
+
+	query := bson.M{}
+	pageID := outboxURL + "?page=true"
+	if before := r.URL.Query().Get("before"); before != "" {
+		cursor, err := strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid before cursor", http.StatusBadRequest)
+			return
+		}
+		query["publishedAt"] = bson.M{"$lt": cursor}
+		pageID = outboxURL + "?page=true&before=" + before
+	}
+
+	var items []storedActivity
+	if err := s.outbox.Find(query).Sort("-publishedAt").Limit(outboxPageSize).All(&items); err != nil {
+		http.Error(w, "failed to load outbox", http.StatusInternalServerError)
+		return
+	}
+
+	page := orderedCollectionPage{
+		Context: "https://www.w3.org/ns/activitystreams",
+		ID:      pageID,
+		Type:    "OrderedCollectionPage",
+		PartOf:  outboxURL,
+	}
+	for _, item := range items {
+		page.OrderedItems = append(page.OrderedItems, item.Activity)
+	}
+	if len(items) == outboxPageSize {
+		oldest := items[len(items)-1].PublishedAt
+		page.Next = outboxURL + "?page=true&before=" + strconv.FormatInt(oldest, 10)
+	}
+
+	w.Header().Set("Content-Type", activityJSONType)
+	json.NewEncoder(w).Encode(page)
+}
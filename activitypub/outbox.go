@@ -0,0 +1,88 @@
+package activitypub
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Note is an ActivityStreams Note, used here to wrap a single forum post.
+type Note struct {
+	Context      string   `json:"@context"`
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	AttributedTo string   `json:"attributedTo"`
+	To           []string `json:"to"`
+	Content      string   `json:"content"`
+	URL          string   `json:"url,omitempty"`
+}
+
+// Create is an ActivityStreams Create{Note} activity.
+type Create struct {
+	Context string   `json:"@context"`
+	ID      string   `json:"id"`
+	Type    string   `json:"type"`
+	Actor   string   `json:"actor"`
+	To      []string `json:"to"`
+	Object  Note     `json:"object"`
+}
+
+// storedActivity is how a published Create{Note} is persisted so it can be
+// served back from the outbox collection.
+type storedActivity struct {
+	ID          string      `bson:"_id"`
+	PublishedAt int64       `bson:"publishedAt"`
+	Activity    interface{} `bson:"activity"`
+}
+
+const publicCollection = "https://www.w3.org/ns/activitystreams#Public"
+
+// NewNote builds the Note + Create activity for a forum post, deriving a
+// stable activity ID from the post's own URL so redelivery is idempotent.
+// postURL is path-escaped before being appended, since it's itself a full
+// URL (scheme, query string, fragment and all) and would otherwise produce
+// a non-conformant id that a remote server reparses differently than we
+// stored it.
+func (s *Server) NewNote(postURL, title, body string, publishedAt int64) Create {
+	noteID := s.actorURL() + "/notes/" + url.PathEscape(postURL)
+	note := Note{
+		Context:      "https://www.w3.org/ns/activitystreams",
+		ID:           noteID,
+		Type:         "Note",
+		AttributedTo: s.actorURL(),
+		To:           []string{publicCollection},
+		Content:      fmt.Sprintf("%s\n\n%s", title, body),
+		URL:          postURL,
+	}
+
+	return Create{
+		Context: "https://www.w3.org/ns/activitystreams",
+		ID:      noteID + "/activity",
+		Type:    "Create",
+		Actor:   s.actorURL(),
+		To:      []string{publicCollection},
+		Object:  note,
+	}
+}
+
+// Publish stores create in the outbox and queues it for delivery to every
+// follower's inbox. Delivery itself happens on runDeliveryWorker, so the
+// caller doesn't wait on follower inboxes unless the delivery queue is full.
+func (s *Server) Publish(create Create, publishedAt int64) error {
+	doc := storedActivity{ID: create.ID, PublishedAt: publishedAt, Activity: create}
+	if _, err := s.outbox.UpsertId(doc.ID, doc); err != nil {
+		return errors.Wrap(err, "failed to persist outbox activity")
+	}
+
+	var followers []follower
+	if err := s.followers.Find(nil).All(&followers); err != nil {
+		return errors.Wrap(err, "failed to load followers for delivery")
+	}
+
+	for _, f := range followers {
+		s.deliveries <- delivery{inbox: f.Inbox, activity: create}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package activitypub
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNewNoteDerivesStableID(t *testing.T) {
+	s := &Server{host: "bot.example.com", username: "kalcor"}
+
+	postURL := "http://forum.sa-mp.com/showthread.php?p=1#post1"
+	create := s.NewNote(postURL, "Title", "Body", 0)
+
+	wantNoteID := s.actorURL() + "/notes/" + url.PathEscape(postURL)
+	if create.Object.ID != wantNoteID {
+		t.Errorf("note id = %q, want %q", create.Object.ID, wantNoteID)
+	}
+	if create.ID != wantNoteID+"/activity" {
+		t.Errorf("create id = %q, want %q", create.ID, wantNoteID+"/activity")
+	}
+
+	again := s.NewNote(postURL, "Title", "Body", 0)
+	if again.Object.ID != create.Object.ID {
+		t.Error("NewNote produced a different id for the same postURL")
+	}
+}
+
+func TestNewNoteIDIsPathSafe(t *testing.T) {
+	s := &Server{host: "bot.example.com", username: "kalcor"}
+
+	create := s.NewNote("http://forum.sa-mp.com/showthread.php?p=1#post1", "Title", "Body", 0)
+
+	if strings.Contains(create.Object.ID, "#") || strings.Contains(create.Object.ID, "?") {
+		t.Errorf("note id %q embeds an unescaped query or fragment delimiter", create.Object.ID)
+	}
+}
+
+func TestNewNoteDifferentPostsGetDifferentIDs(t *testing.T) {
+	s := &Server{host: "bot.example.com", username: "kalcor"}
+
+	a := s.NewNote("http://forum.sa-mp.com/showthread.php?p=1#post1", "A", "body", 0)
+	b := s.NewNote("http://forum.sa-mp.com/showthread.php?p=2#post2", "B", "body", 0)
+
+	if a.Object.ID == b.Object.ID {
+		t.Error("distinct post URLs produced the same note id")
+	}
+}
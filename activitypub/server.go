@@ -0,0 +1,85 @@
+package activitypub
+
+import (
+	"crypto/rsa"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/mgo.v2"
+)
+
+// deliveryQueueSize bounds how many pending deliveries Publish can queue
+// before it starts blocking the caller. It only needs to absorb a burst
+// across every follower of a single post; the worker drains it continuously.
+const deliveryQueueSize = 256
+
+// delivery is a single queued inbox POST, processed by runDeliveryWorker.
+type delivery struct {
+	inbox    string
+	activity interface{}
+}
+
+// Server holds everything needed to serve and federate a single local actor.
+type Server struct {
+	host     string
+	username string
+
+	followers *mgo.Collection
+	outbox    *mgo.Collection
+
+	privateKey   *rsa.PrivateKey
+	publicKeyPem []byte
+
+	// deliveries queues outbound inbox POSTs so Publish doesn't wait on a
+	// follower inbox itself; runDeliveryWorker drains it. Once the queue holds
+	// deliveryQueueSize pending deliveries, Publish blocks until there is room.
+	deliveries chan delivery
+
+	logger *zap.Logger
+}
+
+// NewServer loads (or generates) the actor's keypair from db and returns a
+// Server ready to be mounted on an http.ServeMux.
+func NewServer(db *mgo.Database, host, username string, logger *zap.Logger) (*Server, error) {
+	s := &Server{
+		host:       host,
+		username:   username,
+		followers:  db.C("followers"),
+		outbox:     db.C("outbox"),
+		deliveries: make(chan delivery, deliveryQueueSize),
+		logger:     logger,
+	}
+
+	privateKey, publicKeyPem, err := loadOrCreateKeyPair(db.C("actor_keys"), s.actorURL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up actor keypair")
+	}
+	s.privateKey = privateKey
+	s.publicKeyPem = publicKeyPem
+
+	go s.runDeliveryWorker()
+
+	return s, nil
+}
+
+// runDeliveryWorker drains the delivery queue for as long as the process
+// runs, so Publish doesn't wait on a remote inbox itself. deliverAccept does
+// not use this queue; it delivers directly.
+func (s *Server) runDeliveryWorker() {
+	for job := range s.deliveries {
+		if err := s.deliver(job.inbox, job.activity); err != nil {
+			s.logger.Error("failed to deliver queued activity")
+		}
+	}
+}
+
+// RegisterRoutes mounts the actor, webfinger, inbox, outbox and followers
+// handlers on mux.
+func (s *Server) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/actor", s.handleActor)
+	mux.HandleFunc("/.well-known/webfinger", s.handleWebfinger)
+	mux.HandleFunc("/actor/inbox", s.handleInbox)
+	mux.HandleFunc("/actor/outbox", s.handleOutbox)
+	mux.HandleFunc("/actor/followers", s.handleFollowers)
+}
@@ -0,0 +1,57 @@
+package activitypub
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseSignatureHeader(t *testing.T) {
+	header := `keyId="https://mastodon.example/actor#main-key",algorithm="rsa-sha256",headers="(request-target) host date digest",signature="abc123"`
+
+	params, err := parseSignatureHeader(header)
+	if err != nil {
+		t.Fatalf("parseSignatureHeader: %v", err)
+	}
+
+	if params["keyId"] != "https://mastodon.example/actor#main-key" {
+		t.Errorf("keyId = %q, want actor key URL", params["keyId"])
+	}
+	if params["signature"] != "abc123" {
+		t.Errorf("signature = %q, want %q", params["signature"], "abc123")
+	}
+	if params["headers"] != "(request-target) host date digest" {
+		t.Errorf("headers = %q, want the full header list", params["headers"])
+	}
+}
+
+func TestParseSignatureHeaderRejectsMissingFields(t *testing.T) {
+	if _, err := parseSignatureHeader(`algorithm="rsa-sha256"`); err == nil {
+		t.Error("expected an error for a header missing keyId and signature")
+	}
+}
+
+func TestIsDisallowedActorIP(t *testing.T) {
+	cases := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"10.0.0.5", true},
+		{"192.168.1.1", true},
+		{"0.0.0.0", true},
+		{"93.184.216.34", false},
+	}
+
+	for _, c := range cases {
+		if got := isDisallowedActorIP(net.ParseIP(c.ip)); got != c.want {
+			t.Errorf("isDisallowedActorIP(%s) = %v, want %v", c.ip, got, c.want)
+		}
+	}
+}
+
+func TestValidateRemoteActorURLRejectsNonHTTPS(t *testing.T) {
+	if err := validateRemoteActorURL("http://mastodon.example/actor"); err == nil {
+		t.Error("expected an error for a non-https actor URL")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const defaultHistoryCount = 10
+
+// ActionContext carries the invocation context an Action needs: the app
+// itself, the originating Discord guild/channel (empty for an HTTP-triggered
+// call), and the command/query arguments.
+type ActionContext struct {
+	App       *App
+	GuildID   string
+	ChannelID string
+	Args      []string
+}
+
+// Action is a single bot feature that can be invoked identically from a
+// Discord command or an HTTP admin endpoint; Run's result is rendered as
+// plain text for Discord and as JSON for HTTP.
+type Action struct {
+	Name string
+	Run  func(ctx ActionContext) (interface{}, error)
+}
+
+// profileAction looks up a forum user's profile. It backs both the
+// "!profile <id>" Discord command and "GET /profile/{id}".
+var profileAction = Action{
+	Name: "profile",
+	Run: func(ctx ActionContext) (interface{}, error) {
+		if len(ctx.Args) == 0 {
+			return nil, errors.New("usage: profile <id>")
+		}
+
+		return ctx.App.forumSource.Profile(ctx.Args[0])
+	},
+}
+
+// historyAction reads back a watched user's last N posts from Mongo,
+// without touching the forum at all. It backs "!history <userID> [N]".
+var historyAction = Action{
+	Name: "history",
+	Run: func(ctx ActionContext) (interface{}, error) {
+		if len(ctx.Args) == 0 {
+			return nil, errors.New("usage: history <userID> [N]")
+		}
+
+		count := defaultHistoryCount
+		if len(ctx.Args) > 1 {
+			n, err := strconv.Atoi(ctx.Args[1])
+			if err != nil {
+				return nil, errors.Wrap(err, "N must be an integer")
+			}
+			count = n
+		}
+
+		return ctx.App.recentPosts(ctx.Args[0], count)
+	},
+}
+
+// langAction sets the calling guild's preferred language for every
+// templated message. It backs "!lang set <tag>".
+var langAction = Action{
+	Name: "lang",
+	Run: func(ctx ActionContext) (interface{}, error) {
+		if ctx.GuildID == "" {
+			return nil, errors.New("!lang can only be used from within a server")
+		}
+		if len(ctx.Args) != 2 || ctx.Args[0] != "set" {
+			return ctx.App.Render(ctx.GuildID, "lang_usage", nil)
+		}
+
+		lang := ctx.Args[1]
+		if err := ctx.App.SetGuildLang(ctx.GuildID, lang); err != nil {
+			return nil, err
+		}
+
+		return ctx.App.Render(ctx.GuildID, "lang_set", map[string]string{"Lang": lang})
+	},
+}
+
+// registerActions wires every shared Action up as a Discord command. An
+// action's result is rendered through the same locale templating as every
+// other user-facing message, keyed on "<action name>_result"; langAction
+// already returns pre-rendered text, so it's passed through unchanged.
+func (app *App) registerActions() {
+	for _, action := range []Action{profileAction, historyAction, langAction} {
+		action := action
+		app.commandManager.Register("!"+action.Name, func(guildID, channelID string, args []string) (string, error) {
+			result, err := action.Run(ActionContext{App: app, GuildID: guildID, ChannelID: channelID, Args: args})
+			if err != nil {
+				return "", err
+			}
+
+			if text, ok := result.(string); ok {
+				return text, nil
+			}
+
+			text, err := app.Render(guildID, action.Name+"_result", result)
+			if err != nil {
+				logger.Error("failed to render action result, falling back to plain text")
+				return fmt.Sprintf("%v", result), nil
+			}
+			return text, nil
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/JulietaCorleone/cj/activitypub"
+)
+
+// ConnectActivityPub sets up the fediverse actor (keypair, Mongo-backed
+// followers/outbox). Its routes are served by StartHTTPServer alongside the
+// rest of the admin API. Mastodon and Pleroma users can then follow
+// @kalcor@<host> to receive forum posts as Notes alongside the existing
+// Discord alert.
+func (app *App) ConnectActivityPub() error {
+	server, err := activitypub.NewServer(app.mongo.DB(app.config.MongoDatabase), app.config.ActivityPubHost, "kalcor", logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up activitypub server")
+	}
+	app.activityPub = server
+
+	return nil
+}
+
+// federatePost publishes a detected forum post as a Create{Note} activity to
+// every follower of the local actor, in addition to whatever Discord alert
+// is already firing for it.
+func (app *App) federatePost(post Post) {
+	if app.activityPub == nil {
+		return
+	}
+
+	create := app.activityPub.NewNote(post.URL, post.Title, post.Body, time.Now().Unix())
+	if err := app.activityPub.Publish(create, time.Now().Unix()); err != nil {
+		logger.Error("failed to federate forum post")
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+// Config holds the bot's runtime configuration, loaded from config.json (or
+// the file named by $CONFIG_FILE) before Start is called.
+type Config struct {
+	PrimaryChannel         string  `json:"primaryChannel"`
+	MongoDatabase          string  `json:"mongoDatabase"`
+	ForumSource            string  `json:"forumSource"`
+	ForumRequestsPerSecond float64 `json:"forumRequestsPerSecond"`
+	ActivityPubHost        string  `json:"activityPubHost"`
+	AdminAddr              string  `json:"adminAddr"`
+	AdminToken             string  `json:"adminToken"`
+}
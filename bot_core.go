@@ -1,32 +1,58 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/JulietaCorleone/cj/activitypub"
+	"github.com/JulietaCorleone/cj/poller"
 	"github.com/bwmarrin/discordgo"
 	scraper "github.com/cardigann/go-cloudflare-scraper"
 	"github.com/patrickmn/go-cache"
-	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"gopkg.in/mgo.v2"
 )
 
 // App stores program state
 type App struct {
-	config         Config
-	mongo          *mgo.Session
-	accounts       *mgo.Collection
-	chat           *mgo.Collection
-	discordClient  *discordgo.Session
-	httpClient     *http.Client
-	ready          chan bool
-	cache          *cache.Cache
-	locale         Locale
-	commandManager *CommandManager
+	config          Config
+	mongo           *mgo.Session
+	accounts        *mgo.Collection
+	chat            *mgo.Collection
+	discordClient   *discordgo.Session
+	httpClient      *http.Client
+	ready           chan bool
+	cache           *cache.Cache
+	locale          Locale
+	commandManager  *CommandManager
+	forumSource     ForumSource
+	activityPub     *activitypub.Server
+	poller          *poller.Poller
+	watchers        *watcherRegistry
+	watcherDocs     *mgo.Collection
+	postDocs        *mgo.Collection
+	localeTemplates *localeTemplates
+	guildLanguages  *mgo.Collection
+}
+
+// ConnectForumSource picks a ForumSource implementation based on config.
+// vBulletin's RSS feeds are far cheaper to poll than the HTML scraper, so
+// that's the default; the scraper remains available for forums without RSS.
+func (app *App) ConnectForumSource() {
+	reqPerSec := app.config.ForumRequestsPerSecond
+	if reqPerSec <= 0 {
+		reqPerSec = 1
+	}
+	app.poller = poller.New(reqPerSec, 1)
+
+	switch app.config.ForumSource {
+	case "html":
+		app.forumSource = NewHTMLSource(app)
+	default:
+		app.forumSource = NewVBulletinRSSSource(app, "http://forum.sa-mp.com")
+	}
 }
 
 // Start starts the app with the specified config and blocks until fatal error
@@ -36,10 +62,13 @@ func Start(config Config) {
 		log.Fatal(err)
 	}
 
+	appCache := cache.New(5*time.Minute, 30*time.Second)
+
 	app := App{
 		config:     config,
-		httpClient: &http.Client{Transport: scraper},
-		cache:      cache.New(5*time.Minute, 30*time.Second),
+		httpClient: &http.Client{Transport: newConditionalTransport(scraper, appCache)},
+		cache:      appCache,
+		watchers:   newWatcherRegistry(),
 	}
 
 	configLocation := os.Getenv("CONFIG_FILE")
@@ -51,18 +80,30 @@ func Start(config Config) {
 		zap.Any("config", app.config))
 
 	app.ConnectDB()
+	app.ConnectWatcherStore()
+	app.ConnectLocaleTemplates()
 	app.LoadLanguages()
 	app.StartCommandManager()
+	app.registerActions()
 	app.ConnectDiscord()
+	app.ConnectForumSource()
+
+	if err := app.ConnectActivityPub(); err != nil {
+		logger.Error("failed to start activitypub server, federation disabled")
+	}
+	app.StartHTTPServer()
 
-	app.newPostAlert("3", func() {
-		title, message, err := app.getLatestPost("3")
-		if err != nil {
-			errors.Wrap(err, "failed to get latest kalcor post")
-		} else {
-			app.discordClient.ChannelMessageSend(app.config.PrimaryChannel, fmt.Sprint("**__NEW KALCOR POST__ IN TOPIC: %s**\nPost: %s", title, message))
+	app.resumeWatchers()
+
+	// resumeWatchers already restarted this watch from Mongo on every
+	// restart after the first, so only start it here if it isn't already
+	// running (otherwise AddWatcher's "already watching" guard would log an
+	// error on every single startup).
+	if !watching(app.ListWatchers(), "3") {
+		if err := app.AddWatcher("3", app.config.PrimaryChannel, ""); err != nil {
+			logger.Error("failed to watch kalcor's forum posts")
 		}
-	})
+	}
 
 	done := make(chan bool)
 	<-done
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/xmlpath.v2"
@@ -207,63 +206,39 @@ func (app App) getFirstTenUserVisitorMessages(root *xmlpath.Node) ([]VisitorMess
 	return result, nil
 }
 
-func (app *App) newPostAlert(id string, fn func()) {
-	ticker := time.NewTicker(time.Second * 10)
-	lastPostCount := -1
-
-	go func() {
-		for range ticker.C {
-			fmt.Println("checking profile page")
-			profile, err := app.GetUserProfilePage("http://forum.sa-mp.com/member.php?u=" + id)
-			if err != nil {
-				logger.Error("failed to poll user profile")
-			}
-			fmt.Println(profile)
-
-			if lastPostCount == -1 {
-				lastPostCount = profile.TotalPosts
-				continue
-			}
-
-			if lastPostCount < profile.TotalPosts {
-				fn()
-				lastPostCount = profile.TotalPosts
-			}
-		}
-	}()
-}
-
 //	getLatestPost
 //	Params:
 //		forum id
 //	returns:
+//		string - post title
 //		string - full url
 //		string - Full post
 // 		error - error
 
-func (app App) getLatestPost(id string) (string, string, error) {
+func (app App) getLatestPost(id string) (string, string, string, error) {
 	root, err := app.GetHTMLRoot(fmt.Sprintf("http://forum.sa-mp.com/search.php?do=finduser&u=%s", id))
 	if err != nil {
-		return "", "", errors.Wrap(err, "cannot get user's posts")
+		return "", "", "", errors.Wrap(err, "cannot get user's posts")
 	}
 
 	// Get the first post's title
 	path := xmlpath.MustCompile(`//em/a`)
 	title, ok := path.String(root)
 	if !ok {
-		return "", "", errors.New("cannot get the title of the first post")
+		return "", "", "", errors.New("cannot get the title of the first post")
 	}
 
 	// Get the first post from the list
 	path = xmlpath.MustCompile(`//em/a/@href`)
 	href, ok := path.String(root)
 	if !ok {
-		return "", "", errors.New("cannot get user posts")
+		return "", "", "", errors.New("cannot get user posts")
 	}
 
-	root, err = app.GetHTMLRoot(fmt.Sprintf("http://forum.sa-mp.com/%s", href))
+	postURL := postAbsoluteURL(href)
+	root, err = app.GetHTMLRoot(postURL)
 	if err != nil {
-		return "", "", errors.Wrap(err, "cannot get user post url")
+		return "", "", "", errors.Wrap(err, "cannot get user post url")
 	}
 
 	// Get the post
@@ -271,9 +246,16 @@ func (app App) getLatestPost(id string) (string, string, error) {
 	path = xmlpath.MustCompile(fmt.Sprintf(`//div[@id="post_message_%s"]`, post))
 	message, ok := path.String(root)
 	if !ok {
-		return "", "", errors.New("cannot get the post")
+		return "", "", "", errors.New("cannot get the post")
 	}
 	outputMessage := message
 
-	return title, outputMessage, nil
+	return title, postURL, outputMessage, nil
+}
+
+// postAbsoluteURL joins a forum-relative href (as returned by an xmlpath
+// @href lookup) onto forum.sa-mp.com so it can be stored/compared as a
+// stable post URL.
+func postAbsoluteURL(href string) string {
+	return fmt.Sprintf("http://forum.sa-mp.com/%s", href)
 }
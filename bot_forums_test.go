@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestPostAbsoluteURL(t *testing.T) {
+	cases := []struct {
+		href string
+		want string
+	}{
+		{"showthread.php?p=123#post123", "http://forum.sa-mp.com/showthread.php?p=123#post123"},
+		{"", "http://forum.sa-mp.com/"},
+	}
+
+	for _, c := range cases {
+		if got := postAbsoluteURL(c.href); got != c.want {
+			t.Errorf("postAbsoluteURL(%q) = %q, want %q", c.href, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// StartHTTPServer mounts the admin API, the ActivityPub federation routes
+// and /metrics on a single chi router and serves it on config.AdminAddr.
+func (app *App) StartHTTPServer() {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Use(app.logRequest)
+	r.Use(middleware.Recoverer)
+
+	r.Get("/health", app.handleHealth)
+	r.Get("/metrics", handleMetrics)
+
+	if app.activityPub != nil {
+		// These are public federation protocol endpoints, so they're
+		// mounted outside the bearer-auth group below.
+		apMux := http.NewServeMux()
+		app.activityPub.RegisterRoutes(apMux)
+		r.Mount("/", apMux)
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(app.bearerAuth)
+
+		r.Get("/watchers", app.handleListWatchers)
+		r.Post("/watchers", app.handleAddWatcher)
+		r.Delete("/watchers/{id}", app.handleRemoveWatcher)
+		r.Get("/profile/{id}", app.handleProfile)
+	})
+
+	go func() {
+		if err := http.ListenAndServe(app.config.AdminAddr, r); err != nil {
+			logger.Error("admin http server stopped")
+		}
+	}()
+}
+
+// logRequest is chi middleware that logs each request with zap.
+func (app *App) logRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		logger.Info("http request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", ww.Status()),
+			zap.Duration("duration", time.Since(start)),
+			zap.String("requestID", middleware.GetReqID(r.Context())),
+		)
+	})
+}
+
+// bearerAuth rejects requests that don't present the configured admin
+// bearer token.
+func (app *App) bearerAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token != app.config.AdminToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *App) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+func (app *App) handleListWatchers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(app.ListWatchers())
+}
+
+// addWatcherRequest is the POST /watchers body.
+type addWatcherRequest struct {
+	UserID    string `json:"userID"`
+	ChannelID string `json:"channelID"`
+	Template  string `json:"template"`
+}
+
+func (app *App) handleAddWatcher(w http.ResponseWriter, r *http.Request) {
+	var req addWatcherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		http.Error(w, "userID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := app.AddWatcher(req.UserID, req.ChannelID, req.Template); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (app *App) handleRemoveWatcher(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := app.RemoveWatcher(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) handleProfile(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	result, err := profileAction.Run(ActionContext{App: app, Args: []string{id}})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
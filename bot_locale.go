@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// defaultLang is used whenever a guild hasn't picked a language, and as the
+// fallback when a guild's chosen language is missing a given message.
+const defaultLang = "en"
+
+// localeTemplates holds Go text/template snippets keyed by (langTag,
+// messageKey), on top of which per-guild language selection is layered.
+type localeTemplates struct {
+	mu        sync.RWMutex
+	templates map[string]map[string]*template.Template
+}
+
+// newLocaleTemplates returns a localeTemplates seeded with the bot's
+// default (English) message set.
+func newLocaleTemplates() *localeTemplates {
+	l := &localeTemplates{templates: make(map[string]map[string]*template.Template)}
+
+	l.mustRegister(defaultLang, "new_forum_post",
+		"**__NEW {{.Author}} POST__ IN TOPIC: {{.Title}}**\nPost: {{.Excerpt}}\n{{.URL}}")
+	l.mustRegister(defaultLang, "lang_set", "Language set to {{.Lang}}.")
+	l.mustRegister(defaultLang, "lang_usage", "Usage: !lang set <tag>")
+	l.mustRegister(defaultLang, "profile_result",
+		"**{{.UserName}}** — {{.TotalPosts}} posts, {{.Reputation}} reputation\n{{.BioText}}")
+	l.mustRegister(defaultLang, "history_result",
+		"{{range .}}**{{.Title}}** ({{.Timestamp.Format \"2006-01-02\"}})\n{{end}}")
+
+	return l
+}
+
+// Register parses tmplText and stores it under (langTag, key), replacing
+// any existing template there.
+func (l *localeTemplates) Register(langTag, key, tmplText string) error {
+	tmpl, err := template.New(key).Parse(tmplText)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse template for %s/%s", langTag, key)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.templates[langTag] == nil {
+		l.templates[langTag] = make(map[string]*template.Template)
+	}
+	l.templates[langTag][key] = tmpl
+
+	return nil
+}
+
+func (l *localeTemplates) mustRegister(langTag, key, tmplText string) {
+	if err := l.Register(langTag, key, tmplText); err != nil {
+		panic(err)
+	}
+}
+
+// render looks up (langTag, key) and executes it against data, reporting
+// whether a template was found at all.
+func (l *localeTemplates) render(langTag, key string, data interface{}) (string, bool, error) {
+	l.mu.RLock()
+	tmpl, ok := l.templates[langTag][key]
+	l.mu.RUnlock()
+	if !ok {
+		return "", false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", true, errors.Wrapf(err, "failed to render %s/%s", langTag, key)
+	}
+
+	return buf.String(), true, nil
+}
+
+// guildLangDoc is a guild's persisted language preference, set with
+// "!lang set <tag>".
+type guildLangDoc struct {
+	GuildID string `bson:"_id"`
+	Lang    string `bson:"lang"`
+}
+
+// ConnectLocaleTemplates sets up the default message templates and the
+// per-guild language collection. Called once ConnectDB has established
+// app.mongo.
+func (app *App) ConnectLocaleTemplates() {
+	app.localeTemplates = newLocaleTemplates()
+	app.guildLanguages = app.mongo.DB(app.config.MongoDatabase).C("guild_languages")
+}
+
+// SetGuildLang persists guildID's chosen language tag.
+func (app *App) SetGuildLang(guildID, lang string) error {
+	_, err := app.guildLanguages.UpsertId(guildID, bson.M{"$set": bson.M{"lang": lang}})
+	if err != nil {
+		return errors.Wrap(err, "failed to set guild language")
+	}
+	return nil
+}
+
+// GuildLang returns guildID's chosen language, or defaultLang if it hasn't
+// picked one (or guildID is empty, e.g. an HTTP-triggered render).
+func (app *App) GuildLang(guildID string) (string, error) {
+	if guildID == "" {
+		return defaultLang, nil
+	}
+
+	var doc guildLangDoc
+	err := app.guildLanguages.FindId(guildID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return defaultLang, nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load guild language")
+	}
+
+	return doc.Lang, nil
+}
+
+// guildIDForChannel looks up the guild a Discord channel belongs to, so a
+// stored channelID can be scoped to the right per-guild language. Returns
+// "" (the default language) if the channel can't be resolved.
+func (app *App) guildIDForChannel(channelID string) string {
+	channel, err := app.discordClient.Channel(channelID)
+	if err != nil {
+		return ""
+	}
+	return channel.GuildID
+}
+
+const excerptLength = 200
+
+// excerpt truncates a post body to a template-friendly preview length.
+func excerpt(body string) string {
+	if len(body) <= excerptLength {
+		return body
+	}
+	return body[:excerptLength] + "..."
+}
+
+// Render renders messageKey for guildID in its chosen language, falling
+// back to defaultLang if that language is missing the message.
+func (app *App) Render(guildID, messageKey string, data interface{}) (string, error) {
+	lang, err := app.GuildLang(guildID)
+	if err != nil {
+		return "", err
+	}
+
+	text, found, err := app.localeTemplates.render(lang, messageKey, data)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return text, nil
+	}
+
+	text, found, err = app.localeTemplates.render(defaultLang, messageKey, data)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", errors.Errorf("no template registered for message %q", messageKey)
+	}
+
+	return text, nil
+}
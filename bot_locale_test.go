@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExcerptLeavesShortBodyUnchanged(t *testing.T) {
+	short := "hello there"
+	if got := excerpt(short); got != short {
+		t.Errorf("excerpt(%q) = %q, want unchanged", short, got)
+	}
+}
+
+func TestExcerptTruncatesLongBody(t *testing.T) {
+	body := strings.Repeat("a", excerptLength+50)
+	want := strings.Repeat("a", excerptLength) + "..."
+	if got := excerpt(body); got != want {
+		t.Errorf("excerpt(long) length = %d, want %d", len(got), len(want))
+	}
+}
+
+func TestLocaleTemplatesRender(t *testing.T) {
+	l := newLocaleTemplates()
+	if err := l.Register("en", "greeting", "Hello {{.Name}}"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	text, found, err := l.render("en", "greeting", map[string]string{"Name": "World"})
+	if err != nil || !found || text != "Hello World" {
+		t.Fatalf("render = (%q, %v, %v), want (\"Hello World\", true, nil)", text, found, err)
+	}
+
+	_, found, err = l.render("en", "missing_key", nil)
+	if err != nil || found {
+		t.Fatalf("render(missing key) = (found=%v, err=%v), want (false, nil)", found, err)
+	}
+}
+
+func TestRenderFallsBackToDefaultLangMessage(t *testing.T) {
+	app := &App{localeTemplates: newLocaleTemplates()}
+	if err := app.localeTemplates.Register(defaultLang, "custom", "Value: {{.V}}"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	text, err := app.Render("", "custom", map[string]string{"V": "42"})
+	if err != nil || text != "Value: 42" {
+		t.Fatalf("Render = (%q, %v), want (\"Value: 42\", nil)", text, err)
+	}
+
+	if _, err := app.Render("", "does_not_exist", nil); err == nil {
+		t.Fatal("Render(unregistered key) = nil error, want error")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// forumMetrics tracks lightweight Prometheus-style counters for the forum
+// polling subsystem. There's no need to pull in the full client_golang
+// library for three counters, so they're rendered by hand in handleMetrics.
+type forumMetrics struct {
+	requestsTotal  int64
+	cacheHitsTotal int64
+	backoffMillis  int64
+}
+
+var metrics forumMetrics
+
+func (m *forumMetrics) recordRequest() {
+	atomic.AddInt64(&m.requestsTotal, 1)
+}
+
+func (m *forumMetrics) recordCacheHit() {
+	atomic.AddInt64(&m.cacheHitsTotal, 1)
+}
+
+func (m *forumMetrics) recordBackoff(d time.Duration) {
+	atomic.AddInt64(&m.backoffMillis, d.Milliseconds())
+}
+
+// handleMetrics serves forum_requests_total, forum_cache_hits_total and
+// forum_backoff_seconds in the Prometheus text exposition format.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintf(w, "# TYPE forum_requests_total counter\nforum_requests_total %d\n",
+		atomic.LoadInt64(&metrics.requestsTotal))
+	fmt.Fprintf(w, "# TYPE forum_cache_hits_total counter\nforum_cache_hits_total %d\n",
+		atomic.LoadInt64(&metrics.cacheHitsTotal))
+	fmt.Fprintf(w, "# TYPE forum_backoff_seconds counter\nforum_backoff_seconds %f\n",
+		float64(atomic.LoadInt64(&metrics.backoffMillis))/1000)
+}
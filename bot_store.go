@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// watcherDoc is a persisted watcher: everything needed to resume polling a
+// forum user across a restart.
+type watcherDoc struct {
+	UserID            string    `bson:"_id"`
+	LastSeenPostID    string    `bson:"lastSeenPostID"`
+	LastSeenPostCount int       `bson:"lastSeenPostCount"`
+	LastChecked       time.Time `bson:"lastChecked"`
+	ChannelID         string    `bson:"channelID"`
+	Template          string    `bson:"template"`
+}
+
+// postDoc is a single observed forum post, kept around so `!history` can
+// read it back without hitting the forum.
+type postDoc struct {
+	PostID    string    `bson:"_id"`
+	ThreadID  string    `bson:"threadID"`
+	Title     string    `bson:"title"`
+	Body      string    `bson:"body"`
+	Timestamp time.Time `bson:"timestamp"`
+	UserID    string    `bson:"userID"`
+}
+
+// ConnectWatcherStore opens the watchers and posts collections. Called once
+// ConnectDB has established app.mongo.
+func (app *App) ConnectWatcherStore() {
+	db := app.mongo.DB(app.config.MongoDatabase)
+	app.watcherDocs = db.C("watchers")
+	app.postDocs = db.C("posts")
+}
+
+// loadWatcherState returns the persisted state for userID, and reports
+// whether one was found.
+func (app *App) loadWatcherState(userID string) (watcherDoc, bool, error) {
+	var doc watcherDoc
+	err := app.watcherDocs.FindId(userID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return watcherDoc{}, false, nil
+	}
+	if err != nil {
+		return watcherDoc{}, false, errors.Wrap(err, "failed to load watcher state")
+	}
+
+	return doc, true, nil
+}
+
+// loadAllWatchers returns every persisted watcher, used to resume polling
+// on startup.
+func (app *App) loadAllWatchers() ([]watcherDoc, error) {
+	var docs []watcherDoc
+	if err := app.watcherDocs.Find(nil).All(&docs); err != nil {
+		return nil, errors.Wrap(err, "failed to load watchers")
+	}
+
+	return docs, nil
+}
+
+// postID derives a stable, unique ID for a post from its permalink.
+func postID(post Post) string {
+	return post.URL
+}
+
+// threadID returns the part of a post's permalink before its #post anchor.
+func threadID(post Post) string {
+	if idx := strings.Index(post.URL, "#"); idx != -1 {
+		return post.URL[:idx]
+	}
+	return post.URL
+}
+
+// recordPost upserts watcher and post state for a newly detected post. It
+// returns whether the post is new: the posts collection is keyed by postID,
+// so redelivering the same post after a crash updates nothing and reports
+// isNew=false, giving at-most-once delivery of the Discord/ActivityPub
+// alert for any given post.
+func (app *App) recordPost(userID string, post Post) (isNew bool, err error) {
+	info, err := app.postDocs.UpsertId(postID(post), bson.M{"$setOnInsert": postDoc{
+		PostID:    postID(post),
+		ThreadID:  threadID(post),
+		Title:     post.Title,
+		Body:      post.Body,
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to upsert post")
+	}
+
+	set := bson.M{
+		"lastSeenPostID": postID(post),
+		"lastChecked":    time.Now(),
+	}
+	if post.Count > 0 {
+		set["lastSeenPostCount"] = post.Count
+	}
+
+	if _, err := app.watcherDocs.UpsertId(userID, bson.M{"$set": set}); err != nil {
+		return false, errors.Wrap(err, "failed to upsert watcher state")
+	}
+
+	return info.UpsertedId != nil, nil
+}
+
+// recentPosts returns the last n posts made by userID, newest first.
+func (app *App) recentPosts(userID string, n int) ([]postDoc, error) {
+	var docs []postDoc
+	err := app.postDocs.Find(bson.M{"userID": userID}).Sort("-timestamp").Limit(n).All(&docs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load post history")
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestPostID(t *testing.T) {
+	p := Post{URL: "http://forum.sa-mp.com/showthread.php?p=123#post123"}
+	if got := postID(p); got != p.URL {
+		t.Errorf("postID = %q, want %q", got, p.URL)
+	}
+}
+
+func TestThreadID(t *testing.T) {
+	cases := []struct {
+		url  string
+		want string
+	}{
+		{"http://forum.sa-mp.com/showthread.php?p=123#post123", "http://forum.sa-mp.com/showthread.php?p=123"},
+		{"http://forum.sa-mp.com/showthread.php?p=123", "http://forum.sa-mp.com/showthread.php?p=123"},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		if got := threadID(Post{URL: c.url}); got != c.want {
+			t.Errorf("threadID(%q) = %q, want %q", c.url, got, c.want)
+		}
+	}
+}
+
+func TestWatching(t *testing.T) {
+	watchers := []string{"1", "3", "7"}
+
+	if !watching(watchers, "3") {
+		t.Error("watching(watchers, \"3\") = false, want true")
+	}
+	if watching(watchers, "4") {
+		t.Error("watching(watchers, \"4\") = true, want false")
+	}
+	if watching(nil, "3") {
+		t.Error("watching(nil, \"3\") = true, want false")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// cachedResponse is what we keep in the go-cache instance per URL so a
+// later 304 can be served without re-fetching the page.
+type cachedResponse struct {
+	Header       http.Header
+	Body         []byte
+	ETag         string
+	LastModified string
+}
+
+// conditionalTransport wraps an http.RoundTripper so repeated GETs against
+// the same URL send If-None-Match/If-Modified-Since based on a previously
+// observed ETag/Last-Modified, short-circuiting on 304 instead of
+// re-downloading the whole page: the cached body is returned as a 200.
+type conditionalTransport struct {
+	next  http.RoundTripper
+	cache *cache.Cache
+}
+
+// newConditionalTransport wraps next with conditional-GET caching backed by
+// cache.
+func newConditionalTransport(next http.RoundTripper, cache *cache.Cache) *conditionalTransport {
+	return &conditionalTransport{next: next, cache: cache}
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	metrics.recordRequest()
+
+	key := "etag:" + req.URL.String()
+
+	var cached cachedResponse
+	if v, ok := t.cache.Get(key); ok {
+		cached = v.(cachedResponse)
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached.Body != nil {
+		metrics.recordCacheHit()
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Header = cached.Header
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.Body))
+		return resp, nil
+	}
+
+	if isBackoffWorthy(resp) {
+		return resp, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			entry := cachedResponse{
+				Header:       resp.Header,
+				Body:         body,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+			}
+			if entry.ETag != "" || entry.LastModified != "" {
+				t.cache.Set(key, entry, cache.DefaultExpiration)
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+	}
+
+	return resp, nil
+}
+
+// isBackoffWorthy reports whether resp indicates the caller should back off
+// before retrying: rate limiting, server errors, or a Cloudflare challenge
+// that slipped past the scraper transport.
+func isBackoffWorthy(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Server") == "cloudflare" {
+		return true
+	}
+	return false
+}
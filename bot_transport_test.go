@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+func TestIsBackoffWorthy(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		server string
+		want   bool
+	}{
+		{"ok", http.StatusOK, "", false},
+		{"not found", http.StatusNotFound, "", false},
+		{"too many requests", http.StatusTooManyRequests, "", true},
+		{"internal server error", http.StatusInternalServerError, "", true},
+		{"service unavailable", http.StatusServiceUnavailable, "", true},
+		{"cloudflare service unavailable", http.StatusServiceUnavailable, "cloudflare", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: c.status, Header: http.Header{}}
+			if c.server != "" {
+				resp.Header.Set("Server", c.server)
+			}
+			if got := isBackoffWorthy(resp); got != c.want {
+				t.Errorf("isBackoffWorthy(%d) = %v, want %v", c.status, got, c.want)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestConditionalTransportServesCachedBodyOn304(t *testing.T) {
+	c := cache.New(time.Minute, time.Minute)
+	calls := 0
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Etag": []string{`"v1"`}},
+				Body:       ioutil.NopCloser(strings.NewReader("first body")),
+			}, nil
+		}
+
+		if got := req.Header.Get("If-None-Match"); got != `"v1"` {
+			t.Fatalf("second request If-None-Match = %q, want %q", got, `"v1"`)
+		}
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	transport := newConditionalTransport(next, c)
+
+	req1, _ := http.NewRequest(http.MethodGet, "http://example.com/feed", nil)
+	resp1, err := transport.RoundTrip(req1)
+	if err != nil {
+		t.Fatalf("first RoundTrip: %v", err)
+	}
+	body1, _ := ioutil.ReadAll(resp1.Body)
+	if string(body1) != "first body" {
+		t.Fatalf("first body = %q, want %q", body1, "first body")
+	}
+
+	req2, _ := http.NewRequest(http.MethodGet, "http://example.com/feed", nil)
+	resp2, err := transport.RoundTrip(req2)
+	if err != nil {
+		t.Fatalf("second RoundTrip: %v", err)
+	}
+	if resp2.StatusCode != http.StatusOK {
+		t.Fatalf("second RoundTrip status = %d, want 200 (served from cache)", resp2.StatusCode)
+	}
+	body2, _ := ioutil.ReadAll(resp2.Body)
+	if string(body2) != "first body" {
+		t.Fatalf("second body = %q, want cached %q", body2, "first body")
+	}
+	if calls != 2 {
+		t.Fatalf("next.RoundTrip called %d times, want 2", calls)
+	}
+}
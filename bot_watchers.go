@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// watcherRegistry tracks which forum users currently have an active Watch
+// goroutine, so they can be listed and stopped at runtime through the admin
+// HTTP API. The registry itself is in-memory only; watchers survive a restart
+// through the watchers collection, which resumeWatchers reads on startup.
+type watcherRegistry struct {
+	mu    sync.Mutex
+	stops map[string]chan struct{}
+}
+
+func newWatcherRegistry() *watcherRegistry {
+	return &watcherRegistry{stops: make(map[string]chan struct{})}
+}
+
+// AddWatcher starts watching userID for new posts, forwarding each one
+// through the same Discord+ActivityPub pipeline as any other watch.
+// channelID and template are only used to seed a brand new watcher document;
+// an existing one resumes from its persisted state and keeps its own
+// channelID/template.
+func (app *App) AddWatcher(userID, channelID, template string) error {
+	app.watchers.mu.Lock()
+	if _, exists := app.watchers.stops[userID]; exists {
+		app.watchers.mu.Unlock()
+		return errors.Errorf("already watching user %s", userID)
+	}
+	stop := make(chan struct{})
+	app.watchers.stops[userID] = stop
+	app.watchers.mu.Unlock()
+
+	doc, found, err := app.loadWatcherState(userID)
+	if err != nil {
+		app.watchers.mu.Lock()
+		delete(app.watchers.stops, userID)
+		app.watchers.mu.Unlock()
+		return err
+	}
+
+	state := WatchState{Known: found, LastSeenPostID: doc.LastSeenPostID, LastSeenCount: doc.LastSeenPostCount}
+	if !found {
+		if _, err := app.watcherDocs.UpsertId(userID, bson.M{"$setOnInsert": watcherDoc{
+			UserID:    userID,
+			ChannelID: channelID,
+			Template:  template,
+		}}); err != nil {
+			app.watchers.mu.Lock()
+			delete(app.watchers.stops, userID)
+			app.watchers.mu.Unlock()
+			return errors.Wrap(err, "failed to create watcher")
+		}
+		doc.ChannelID = channelID
+		doc.Template = template
+	}
+
+	posts, err := app.forumSource.Watch(userID, state, stop)
+	if err != nil {
+		app.watchers.mu.Lock()
+		delete(app.watchers.stops, userID)
+		app.watchers.mu.Unlock()
+		return errors.Wrap(err, "failed to start watcher")
+	}
+
+	go func() {
+		for post := range posts {
+			isNew, err := app.recordPost(userID, post)
+			if err != nil {
+				logger.Error("failed to record post, skipping alert")
+				continue
+			}
+			if !isNew {
+				continue
+			}
+
+			author := userID
+			if profile, err := app.forumSource.Profile(userID); err != nil {
+				logger.Error("failed to resolve watched user's profile, alerting with raw user ID")
+			} else {
+				author = profile.UserName
+			}
+
+			message, err := app.Render(app.guildIDForChannel(doc.ChannelID), "new_forum_post", map[string]string{
+				"Title":   post.Title,
+				"URL":     post.URL,
+				"Author":  author,
+				"Excerpt": excerpt(post.Body),
+			})
+			if err != nil {
+				logger.Error("failed to render forum post alert, falling back to plain text")
+				message = fmt.Sprintf("**__NEW POST__ IN TOPIC: %s**\nPost: %s", post.Title, post.Body)
+			}
+
+			app.discordClient.ChannelMessageSend(doc.ChannelID, message)
+			app.federatePost(post)
+		}
+	}()
+
+	return nil
+}
+
+// resumeWatchers restarts polling for every watcher persisted from a
+// previous run, picking each one back up from its saved state.
+func (app *App) resumeWatchers() {
+	docs, err := app.loadAllWatchers()
+	if err != nil {
+		logger.Error("failed to load persisted watchers, starting with none")
+		return
+	}
+
+	for _, doc := range docs {
+		if err := app.AddWatcher(doc.UserID, doc.ChannelID, doc.Template); err != nil {
+			logger.Error("failed to resume watcher")
+		}
+	}
+}
+
+// RemoveWatcher stops watching userID.
+func (app *App) RemoveWatcher(userID string) error {
+	app.watchers.mu.Lock()
+	defer app.watchers.mu.Unlock()
+
+	stop, exists := app.watchers.stops[userID]
+	if !exists {
+		return errors.Errorf("not watching user %s", userID)
+	}
+
+	close(stop)
+	delete(app.watchers.stops, userID)
+	return nil
+}
+
+// ListWatchers returns the forum user IDs currently being watched.
+func (app *App) ListWatchers() []string {
+	app.watchers.mu.Lock()
+	defer app.watchers.mu.Unlock()
+
+	result := make([]string, 0, len(app.watchers.stops))
+	for id := range app.watchers.stops {
+		result = append(result, id)
+	}
+
+	return result
+}
+
+// watching reports whether userID appears in watchers, as returned by
+// ListWatchers.
+func watching(watchers []string, userID string) bool {
+	for _, id := range watchers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+// Package poller schedules repeated checks against external resources
+// without hammering them: each job has its own interval and backs off under
+// failure, while a single token bucket caps the total request rate shared
+// across every job.
+package poller
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// PollJob describes a single watched resource.
+type PollJob struct {
+	URL         string
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Backoff     Backoff
+	// OnBackoff, if set, is called with the new interval every time check
+	// fails and Run widens the interval, so callers can record how much
+	// time is being spent backed off.
+	OnBackoff func(time.Duration)
+}
+
+// Backoff computes the next retry interval after consecutive failures,
+// using full jitter so a burst of simultaneously-failing jobs doesn't
+// retry in lockstep.
+type Backoff struct {
+	Base time.Duration
+}
+
+// Next returns a jittered exponential backoff duration for the given number
+// of consecutive failures, floored at min and capped at max. The floor keeps
+// a failing job from retrying faster than its normal interval: full jitter
+// down to zero would let it spin against the shared rate limiter instead of
+// backing off.
+func (b Backoff) Next(failures int, min, max time.Duration) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = time.Second
+	}
+
+	ceiling := base << uint(failures)
+	if ceiling <= 0 || ceiling > max {
+		ceiling = max
+	}
+	if ceiling <= min {
+		return min
+	}
+
+	return min + time.Duration(rand.Int63n(int64(ceiling-min)))
+}
+
+// Poller runs PollJobs on their own schedules while sharing a single
+// token-bucket rate limit across all of them, so the total request rate
+// against forum.sa-mp.com stays bounded regardless of how many resources
+// are being watched.
+type Poller struct {
+	limiter *rate.Limiter
+}
+
+// New returns a Poller allowing at most reqPerSec requests/sec, with bursts
+// up to burst, shared across every job it runs.
+func New(reqPerSec float64, burst int) *Poller {
+	return &Poller{limiter: rate.NewLimiter(rate.Limit(reqPerSec), burst)}
+}
+
+// Run calls check on job's schedule until stop is closed. check should
+// return a non-nil error for failed/backoff-worthy attempts (5xx, 429,
+// Cloudflare challenges); Run widens the interval on failure and resets it
+// to MinInterval as soon as a check succeeds again. Ticks that arrive while
+// the shared rate limit is exhausted are skipped rather than queued.
+func (p *Poller) Run(job PollJob, stop <-chan struct{}, check func() error) {
+	interval := job.MinInterval
+	failures := 0
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		if !p.limiter.Allow() {
+			continue
+		}
+
+		if err := check(); err != nil {
+			failures++
+			interval = job.Backoff.Next(failures, job.MinInterval, job.MaxInterval)
+			if job.OnBackoff != nil {
+				job.OnBackoff(interval)
+			}
+			continue
+		}
+
+		failures = 0
+		interval = job.MinInterval
+	}
+}
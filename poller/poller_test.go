@@ -0,0 +1,74 @@
+package poller
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffNextStaysWithinBounds(t *testing.T) {
+	b := Backoff{Base: time.Second}
+	min := 100 * time.Millisecond
+	max := 10 * time.Second
+
+	for _, failures := range []int{0, 1, 5, 20, 63} {
+		for i := 0; i < 20; i++ {
+			d := b.Next(failures, min, max)
+			if d < min || d > max {
+				t.Fatalf("Next(%d, %s, %s) = %s, want within [%s, %s]", failures, min, max, d, min, max)
+			}
+		}
+	}
+}
+
+func TestBackoffNextDefaultsBaseToOneSecond(t *testing.T) {
+	b := Backoff{}
+	min := time.Second
+	max := time.Minute
+
+	// A 1s base at zero failures gives a 1s ceiling, so Next returns exactly
+	// min; a zero base would instead jitter anywhere up to max.
+	if d := b.Next(0, min, max); d != min {
+		t.Fatalf("Next(0, %s, %s) = %s, want %s", min, max, d, min)
+	}
+}
+
+func TestBackoffNextNeverGoesBelowMin(t *testing.T) {
+	b := Backoff{Base: time.Millisecond}
+	min := 50 * time.Millisecond
+	max := time.Second
+
+	for i := 0; i < 50; i++ {
+		if d := b.Next(0, min, max); d < min {
+			t.Fatalf("Next(0, %s, %s) = %s, want >= %s", min, max, d, min)
+		}
+	}
+}
+
+func TestRunCallsOnBackoffOnFailure(t *testing.T) {
+	p := New(1000, 1000)
+
+	backoffs := make(chan time.Duration, 1)
+	stop := make(chan struct{})
+
+	job := PollJob{
+		MinInterval: time.Millisecond,
+		MaxInterval: 10 * time.Millisecond,
+		Backoff:     Backoff{Base: time.Millisecond},
+		OnBackoff:   func(d time.Duration) { backoffs <- d },
+	}
+
+	go p.Run(job, stop, func() error {
+		return errors.New("check failed")
+	})
+	defer close(stop)
+
+	select {
+	case d := <-backoffs:
+		if d < 0 || d > job.MaxInterval {
+			t.Errorf("OnBackoff called with %s, want within [0, %s]", d, job.MaxInterval)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnBackoff was never called after a failing check")
+	}
+}